@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultStreamPollInterval = 10 * time.Second
+	subscriberBufferSize      = 16
+	keepaliveInterval         = 15 * time.Second
+)
+
+// streamPollInterval is how often the broker checks the Firebase HN API
+// for new story IDs. Configurable via STREAM_POLL_INTERVAL (a Go duration
+// string, e.g. "5s").
+func streamPollInterval() time.Duration {
+	if raw := os.Getenv("STREAM_POLL_INTERVAL"); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil {
+			return d
+		}
+	}
+	return defaultStreamPollInterval
+}
+
+// broker fans newly observed stories out to subscribers, grouped by story
+// type. Each story type gets exactly one poller goroutine, started lazily
+// on its first subscriber. The package-level storyBroker's pollers are
+// meant to run for the process lifetime; Stop exists for tests, which
+// create their own *broker instances and must not leak pollers into
+// later tests.
+type broker struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan Story]struct{}
+	seen        map[string]map[int]struct{}
+	started     map[string]bool
+	stop        map[string]chan struct{}
+}
+
+func newBroker() *broker {
+	return &broker{
+		subscribers: make(map[string]map[chan Story]struct{}),
+		seen:        make(map[string]map[int]struct{}),
+		started:     make(map[string]bool),
+		stop:        make(map[string]chan struct{}),
+	}
+}
+
+var storyBroker = newBroker()
+
+// Subscribe registers a new subscriber for storyType and returns the
+// channel it will receive stories on. Callers must Unsubscribe when done.
+func (b *broker) Subscribe(storyType string) chan Story {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan Story, subscriberBufferSize)
+	if b.subscribers[storyType] == nil {
+		b.subscribers[storyType] = make(map[chan Story]struct{})
+	}
+	b.subscribers[storyType][ch] = struct{}{}
+
+	if !b.started[storyType] {
+		b.started[storyType] = true
+		stop := make(chan struct{})
+		b.stop[storyType] = stop
+		go b.poll(storyType, stop)
+	}
+
+	return ch
+}
+
+// Stop halts storyType's poller goroutine. The package-level storyBroker
+// never calls this; it's for tests that construct their own broker and
+// must tear it down before the test returns.
+func (b *broker) Stop(storyType string) {
+	b.mu.Lock()
+	stop, ok := b.stop[storyType]
+	if ok {
+		delete(b.stop, storyType)
+		b.started[storyType] = false
+	}
+	b.mu.Unlock()
+
+	if ok {
+		close(stop)
+	}
+}
+
+// Unsubscribe removes a subscriber and closes its channel.
+func (b *broker) Unsubscribe(storyType string, ch chan Story) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.subscribers[storyType], ch)
+	close(ch)
+}
+
+// publish fans a story out to every current subscriber of storyType. Slow
+// consumers have their oldest buffered story dropped to make room rather
+// than blocking the poller.
+func (b *broker) publish(storyType string, story Story) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers[storyType] {
+		select {
+		case ch <- story:
+		default:
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- story:
+			default:
+			}
+		}
+	}
+}
+
+// poll periodically re-fetches storyType's ID list and publishes any IDs
+// not seen on the previous poll, until stop is closed. The first poll only
+// establishes the baseline so startup doesn't fire an event per existing
+// story.
+func (b *broker) poll(storyType string, stop <-chan struct{}) {
+	endpoint, err := storyEndpoint(storyType)
+	if err != nil {
+		return
+	}
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout())
+		ids, err := fetchStoryIDs(ctx, endpoint)
+		cancel()
+		if err == nil {
+			b.publishNewIDs(storyType, ids)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(streamPollInterval()):
+		}
+	}
+}
+
+func (b *broker) publishNewIDs(storyType string, ids []int) {
+	b.mu.Lock()
+	previous, haveBaseline := b.seen[storyType]
+	current := make(map[int]struct{}, len(ids))
+
+	var newIDs []int
+	for _, id := range ids {
+		if _, dup := current[id]; dup {
+			continue
+		}
+		current[id] = struct{}{}
+		if _, ok := previous[id]; !ok && haveBaseline {
+			newIDs = append(newIDs, id)
+		}
+	}
+	b.seen[storyType] = current
+	b.mu.Unlock()
+
+	for _, id := range newIDs {
+		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout())
+		item, err := fetchItem(ctx, id)
+		cancel()
+		if err != nil {
+			continue
+		}
+
+		if storyType == "show" && !strings.HasPrefix(item.Title, "Show HN:") {
+			continue
+		}
+		if storyType == "ask" && !strings.HasPrefix(item.Title, "Ask HN:") {
+			continue
+		}
+
+		b.publish(storyType, Story{
+			ID:          item.ID,
+			Title:       item.Title,
+			URL:         item.URL,
+			Points:      item.Score,
+			SubmittedBy: item.By,
+			CreatedAt:   time.Unix(item.Time, 0),
+			CommentsURL: fmt.Sprintf("https://news.ycombinator.com/item?id=%d", item.ID),
+			Type:        storyType,
+		})
+	}
+}
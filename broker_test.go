@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBrokerPublishFanOut asserts every subscriber of a story type receives
+// a published story, and unrelated story types don't.
+func TestBrokerPublishFanOut(t *testing.T) {
+	b := newBroker()
+	b.started["top"] = true // prevent Subscribe from starting real pollers
+	b.started["show"] = true
+
+	ch1 := b.Subscribe("top")
+	ch2 := b.Subscribe("top")
+	other := b.Subscribe("show")
+
+	story := Story{ID: 1, Title: "Example", Type: "top"}
+	b.publish("top", story)
+
+	assert.Equal(t, story, <-ch1)
+	assert.Equal(t, story, <-ch2)
+
+	select {
+	case <-other:
+		t.Fatal("subscriber of a different story type should not receive the story")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+// TestBrokerPublishDropsOldestOnSlowConsumer asserts a full subscriber
+// buffer drops its oldest entry rather than blocking the publisher.
+func TestBrokerPublishDropsOldestOnSlowConsumer(t *testing.T) {
+	b := newBroker()
+	b.started["top"] = true
+
+	ch := b.Subscribe("top")
+
+	for i := 0; i < subscriberBufferSize+5; i++ {
+		b.publish("top", Story{ID: i, Type: "top"})
+	}
+
+	first := <-ch
+	assert.Greater(t, first.ID, 0, "oldest entries should have been dropped, not the newest")
+}
+
+// TestBrokerPollPublishesOnlyNewIDs uses a channel-driven fake HN backend to
+// assert the poller's first pass establishes a baseline (no events) and
+// subsequent passes publish only newly appeared IDs to every subscriber.
+func TestBrokerPollPublishesOnlyNewIDs(t *testing.T) {
+	os.Setenv("STREAM_POLL_INTERVAL", "5ms")
+	defer os.Unsetenv("STREAM_POLL_INTERVAL")
+
+	idLists := make(chan []int, 4)
+	idLists <- []int{1}
+	idLists <- []int{1}
+	idLists <- []int{1, 2}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v0/topstories.json", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case ids := <-idLists:
+			json.NewEncoder(w).Encode(ids)
+		default:
+			json.NewEncoder(w).Encode([]int{1, 2})
+		}
+	})
+	mux.HandleFunc("/v0/item/2.json", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(HNItem{ID: 2, Title: "New Story", By: "tester", Time: time.Now().Unix()})
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	origBaseURL, origClient := hnBaseURL, httpClient
+	hnBaseURL = server.URL
+	httpClient = server.Client()
+	defer func() { hnBaseURL, httpClient = origBaseURL, origClient }()
+
+	b := newBroker()
+	defer b.Stop("top") // last-registered defer: runs before the globals-restore and server.Close defers above
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	subscribers := []chan Story{b.Subscribe("top"), b.Subscribe("top")}
+	for _, ch := range subscribers {
+		go func(ch chan Story) {
+			defer wg.Done()
+			select {
+			case story := <-ch:
+				assert.Equal(t, 2, story.ID)
+			case <-time.After(2 * time.Second):
+				t.Error("timed out waiting for new story event")
+			}
+		}(ch)
+	}
+
+	wg.Wait()
+}
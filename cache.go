@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	cacheExpiration = 5 * time.Minute
+	maxStories      = 30
+)
+
+// Cache stores values keyed by an arbitrary string, with a per-entry TTL.
+// It's generic over the stored value so unrelated callers (story lists,
+// comment trees, ...) can each get their own Cache[T] while sharing one
+// pluggable backend. Implementations must be safe for concurrent use.
+type Cache[T any] interface {
+	Get(ctx context.Context, key string) (T, bool)
+	Set(ctx context.Context, key string, value T, ttl time.Duration)
+	Invalidate(key string)
+}
+
+// newCache selects a Cache implementation based on the CACHE_BACKEND env
+// var ("memory", the default, or "redis"). Redis is configured via
+// REDIS_URL; keyPrefix namespaces this cache's keys from any other Cache
+// sharing the same Redis instance (e.g. "stories" vs "comments").
+func newCache[T any](keyPrefix string) Cache[T] {
+	switch os.Getenv("CACHE_BACKEND") {
+	case "redis":
+		client := redis.NewClient(redisOptions(os.Getenv("REDIS_URL")))
+		return &redisCache[T]{client: client, keyPrefix: keyPrefix}
+	default:
+		return newMemoryCache[T]()
+	}
+}
+
+// cacheTTL returns the configured default cache TTL, falling back to
+// cacheExpiration if CACHE_TTL is unset or invalid.
+func cacheTTL() time.Duration {
+	if raw := os.Getenv("CACHE_TTL"); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil {
+			return d
+		}
+	}
+	return cacheExpiration
+}
+
+// redisOptions parses url (a redis:// URL, or a bare host:port) into
+// *redis.Options. Building the client from the full parsed Options, rather
+// than lifting out just Addr, preserves auth, DB selection, and TLS
+// settings the URL encodes. Falls back to a bare localhost default if url
+// is empty, and uses url itself as the Addr if it doesn't parse as a URL.
+func redisOptions(url string) *redis.Options {
+	if url == "" {
+		return &redis.Options{Addr: "localhost:6379"}
+	}
+	if opts, err := redis.ParseURL(url); err == nil {
+		return opts
+	}
+	return &redis.Options{Addr: url}
+}
+
+var cache = newCache[[]Story]("stories")
+
+// memoryCache is the original in-process StoriesCache, generalized to
+// implement Cache[T] for any value type.
+type memoryCache[T any] struct {
+	values    map[string]T
+	expiresAt map[string]time.Time
+	mutex     sync.RWMutex
+}
+
+func newMemoryCache[T any]() *memoryCache[T] {
+	return &memoryCache[T]{
+		values:    make(map[string]T),
+		expiresAt: make(map[string]time.Time),
+	}
+}
+
+func (mc *memoryCache[T]) Get(_ context.Context, key string) (T, bool) {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	var zero T
+	expiresAt, ok := mc.expiresAt[key]
+	if !ok || time.Now().After(expiresAt) {
+		return zero, false
+	}
+
+	value, ok := mc.values[key]
+	return value, ok
+}
+
+func (mc *memoryCache[T]) Set(_ context.Context, key string, value T, ttl time.Duration) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	mc.values[key] = value
+	mc.expiresAt[key] = time.Now().Add(ttl)
+}
+
+func (mc *memoryCache[T]) Invalidate(key string) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	delete(mc.values, key)
+	delete(mc.expiresAt, key)
+}
+
+// redisCache stores values JSON-encoded under a keyPrefix-namespaced key.
+type redisCache[T any] struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+func (rc *redisCache[T]) Get(ctx context.Context, key string) (T, bool) {
+	var value T
+
+	raw, err := rc.client.Get(ctx, rc.redisKey(key)).Bytes()
+	if err != nil {
+		return value, false
+	}
+
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return value, false
+	}
+
+	return value, true
+}
+
+func (rc *redisCache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+
+	rc.client.Set(ctx, rc.redisKey(key), raw, ttl)
+}
+
+func (rc *redisCache[T]) Invalidate(key string) {
+	rc.client.Del(context.Background(), rc.redisKey(key))
+}
+
+func (rc *redisCache[T]) redisKey(key string) string {
+	return fmt.Sprintf("%s:%s", rc.keyPrefix, key)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFetchStoriesSingleflight asserts that concurrent cache misses for the
+// same story type coalesce into exactly one upstream fetch.
+func TestFetchStoriesSingleflight(t *testing.T) {
+	var topCalls int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v0/topstories.json", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&topCalls, 1)
+		time.Sleep(50 * time.Millisecond) // give concurrent callers time to pile up
+		json.NewEncoder(w).Encode([]int{1})
+	})
+	mux.HandleFunc("/v0/item/1.json", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(HNItem{ID: 1, Title: "Hello", By: "tester", Time: time.Now().Unix()})
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	origBaseURL, origClient, origCache := hnBaseURL, httpClient, cache
+	hnBaseURL = server.URL
+	httpClient = server.Client()
+	cache = newMemoryCache[[]Story]()
+	defer func() {
+		hnBaseURL, httpClient, cache = origBaseURL, origClient, origCache
+	}()
+
+	const concurrency = 10
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			_, err := fetchStories(context.Background(), "top")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&topCalls), "expected exactly one upstream call across concurrent cold-cache fetches")
+}
+
+// TestRedisOptionsPreservesURLFields asserts that auth, DB selection, and
+// host/port encoded in a redis:// URL all survive into the resulting
+// *redis.Options, not just the address.
+func TestRedisOptionsPreservesURLFields(t *testing.T) {
+	opts := redisOptions("redis://:mypassword@prod-redis:6379/2")
+	assert.Equal(t, "prod-redis:6379", opts.Addr)
+	assert.Equal(t, "mypassword", opts.Password)
+	assert.Equal(t, 2, opts.DB)
+}
+
+func TestRedisOptionsDefaultsWhenUnset(t *testing.T) {
+	assert.Equal(t, "localhost:6379", redisOptions("").Addr)
+}
+
+func TestRedisOptionsFallsBackToBareAddr(t *testing.T) {
+	opts := redisOptions("not-a-url")
+	assert.Equal(t, "not-a-url", opts.Addr)
+}
+
+func TestMemoryCacheGetSetInvalidate(t *testing.T) {
+	mc := newMemoryCache[[]Story]()
+	ctx := context.Background()
+
+	_, ok := mc.Get(ctx, "top")
+	assert.False(t, ok, "cold cache should miss")
+
+	stories := []Story{{ID: 1, Title: "Example"}}
+	mc.Set(ctx, "top", stories, cacheExpiration)
+
+	got, ok := mc.Get(ctx, "top")
+	assert.True(t, ok)
+	assert.Equal(t, stories, got)
+
+	mc.Invalidate("top")
+	_, ok = mc.Get(ctx, "top")
+	assert.False(t, ok, "cache should miss after invalidation")
+}
+
+// TestMemoryCacheHonorsPerCallTTL asserts Set's ttl argument governs
+// expiry, not the package-level cacheTTL default.
+func TestMemoryCacheHonorsPerCallTTL(t *testing.T) {
+	mc := newMemoryCache[[]Story]()
+	ctx := context.Background()
+
+	mc.Set(ctx, "top", []Story{{ID: 1}}, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+
+	_, ok := mc.Get(ctx, "top")
+	assert.False(t, ok, "entry should have expired per its own short ttl, not the longer default cacheTTL")
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	defaultCommentMaxDepth  = 6
+	defaultCommentMaxNodes  = 500
+	defaultCommentsCacheTTL = 60 * time.Second
+)
+
+// Comment is a single node in a story's HN comment thread.
+type Comment struct {
+	ID        int       `json:"id"`
+	By        string    `json:"by"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+	Parent    int       `json:"parent"`
+	Replies   []Comment `json:"replies,omitempty"`
+	Deleted   bool      `json:"deleted,omitempty"`
+	Dead      bool      `json:"dead,omitempty"`
+}
+
+// FlatComment is a Comment stripped of nested Replies, annotated with its
+// depth in the tree, for clients that render a list rather than a tree.
+type FlatComment struct {
+	Comment
+	Depth int `json:"depth"`
+}
+
+// fetchComments walks rootID's Kids recursively via the Firebase HN item
+// endpoint, using the same bounded worker pool as story fetches at each
+// level. It stops descending past maxDepth and stops fetching once
+// maxNodes items have been requested, to protect against pathological
+// threads. Already-fetched IDs are memoized for the duration of the call.
+func fetchComments(ctx context.Context, rootID, maxDepth, maxNodes int) ([]Comment, error) {
+	root, err := fetchItem(ctx, rootID)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &commentFetcher{fetched: make(map[int]*HNItem), maxNodes: maxNodes}
+	return f.children(ctx, root.Kids, rootID, 1, maxDepth)
+}
+
+// commentFetcher tracks per-request memoization and the total-node budget
+// shared across every level of the recursive fetch.
+type commentFetcher struct {
+	mu        sync.Mutex
+	fetched   map[int]*HNItem
+	nodeCount int
+	maxNodes  int
+}
+
+// fetchOne fetches (or returns the memoized) item for id, or nil if the
+// per-request node budget has been exhausted.
+func (f *commentFetcher) fetchOne(ctx context.Context, id int) (*HNItem, error) {
+	f.mu.Lock()
+	if item, ok := f.fetched[id]; ok {
+		f.mu.Unlock()
+		return item, nil
+	}
+	if f.nodeCount >= f.maxNodes {
+		f.mu.Unlock()
+		return nil, nil
+	}
+	f.nodeCount++
+	f.mu.Unlock()
+
+	item, err := fetchItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	f.fetched[id] = item
+	f.mu.Unlock()
+
+	return item, nil
+}
+
+// children fetches ids concurrently (bounded to itemFetchWorkers) and
+// recursively resolves each item's own replies, up to maxDepth.
+func (f *commentFetcher) children(ctx context.Context, ids []int, parent, depth, maxDepth int) ([]Comment, error) {
+	if depth > maxDepth || len(ids) == 0 {
+		return nil, nil
+	}
+
+	items := make([]*HNItem, len(ids))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(itemFetchWorkers)
+	for i, id := range ids {
+		i, id := i, id
+		g.Go(func() error {
+			item, err := f.fetchOne(gctx, id)
+			if err != nil {
+				// A single failed comment shouldn't fail the whole tree.
+				return nil
+			}
+			items[i] = item
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	comments := make([]Comment, 0, len(ids))
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+
+		replies, err := f.children(ctx, item.Kids, item.ID, depth+1, maxDepth)
+		if err != nil {
+			return nil, err
+		}
+
+		comments = append(comments, Comment{
+			ID:        item.ID,
+			By:        item.By,
+			Text:      item.Text,
+			CreatedAt: time.Unix(item.Time, 0),
+			Parent:    parent,
+			Replies:   replies,
+			Deleted:   item.Deleted,
+			Dead:      item.Dead,
+		})
+	}
+
+	return comments, nil
+}
+
+// flattenComments renders a comment tree as a pre-order slice, recording
+// each comment's depth for clients that display a list instead of a tree.
+func flattenComments(comments []Comment, depth int) []FlatComment {
+	flat := make([]FlatComment, 0, len(comments))
+	for _, c := range comments {
+		replies := c.Replies
+		c.Replies = nil
+		flat = append(flat, FlatComment{Comment: c, Depth: depth})
+		flat = append(flat, flattenComments(replies, depth+1)...)
+	}
+	return flat
+}
+
+// commentTreeCache caches assembled comment trees, using the same
+// pluggable Cache backend as story lists (CACHE_BACKEND) but under its own
+// key prefix and a much shorter default TTL: comment counts change far
+// more often than the story lists do.
+var commentTreeCache = newCache[[]Comment]("comments")
+
+// commentsCacheTTL is configurable via COMMENTS_CACHE_TTL (a Go duration
+// string, e.g. "30s").
+func commentsCacheTTL() time.Duration {
+	if raw := os.Getenv("COMMENTS_CACHE_TTL"); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil {
+			return d
+		}
+	}
+	return defaultCommentsCacheTTL
+}
+
+func commentsCacheKey(rootID, maxDepth, maxNodes int) string {
+	return fmt.Sprintf("%d:%d:%d", rootID, maxDepth, maxNodes)
+}
+
+// @Summary     Get story comments
+// @Description Get the comment tree for a story. Pass ?flat=true for a pre-order flat slice with a depth field instead of a nested tree.
+// @Tags        comments
+// @Produce     json
+// @Param       type path string true "Story ID (named 'type' only because gin requires the same wildcard name as the sibling /stories/:type and /stories/:type/stream routes; this path segment is always a numeric story ID, never a story type)"
+// @Param       flat query bool false "Return comments as a flat pre-order slice"
+// @Success     200 {array} Comment
+// @Failure     400 {object} ErrorResponse
+// @Failure     500 {object} ErrorResponse
+// @Router      /stories/{type}/comments [get]
+func getComments(c *gin.Context) {
+	// c.Param("type") holds the story ID here, not a story type — see the
+	// @Param doc above for why gin forces this route to reuse that name.
+	rootID, err := strconv.Atoi(c.Param("type"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid story id"})
+		return
+	}
+
+	key := commentsCacheKey(rootID, defaultCommentMaxDepth, defaultCommentMaxNodes)
+	comments, ok := commentTreeCache.Get(c.Request.Context(), key)
+	if !ok {
+		comments, err = fetchComments(c.Request.Context(), rootID, defaultCommentMaxDepth, defaultCommentMaxNodes)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+		commentTreeCache.Set(c.Request.Context(), key, comments, commentsCacheTTL())
+	}
+
+	if strings.EqualFold(c.Query("flat"), "true") {
+		c.JSON(http.StatusOK, flattenComments(comments, 0))
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeHNItemsServer serves a fixed set of HNItem fixtures keyed by ID, for
+// tests that need a comment tree to walk.
+func fakeHNItemsServer(items map[int]HNItem) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v0/item/", func(w http.ResponseWriter, r *http.Request) {
+		var id int
+		fmt.Sscanf(r.URL.Path, "/v0/item/%d.json", &id)
+		item, ok := items[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(item)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestFetchCommentsBuildsTree(t *testing.T) {
+	items := map[int]HNItem{
+		1: {ID: 1, Text: "root", By: "op", Time: time.Now().Unix(), Kids: []int{2, 3}},
+		2: {ID: 2, Text: "reply one", By: "alice", Time: time.Now().Unix(), Kids: []int{4}},
+		3: {ID: 3, Text: "reply two", By: "bob", Time: time.Now().Unix()},
+		4: {ID: 4, Text: "nested reply", By: "carol", Time: time.Now().Unix()},
+	}
+
+	server := fakeHNItemsServer(items)
+	defer server.Close()
+
+	origBaseURL, origClient := hnBaseURL, httpClient
+	hnBaseURL = server.URL
+	httpClient = server.Client()
+	defer func() { hnBaseURL, httpClient = origBaseURL, origClient }()
+
+	comments, err := fetchComments(context.Background(), 1, defaultCommentMaxDepth, defaultCommentMaxNodes)
+	assert.NoError(t, err)
+	assert.Len(t, comments, 2)
+	assert.Equal(t, "alice", comments[0].By)
+	assert.Len(t, comments[0].Replies, 1)
+	assert.Equal(t, "carol", comments[0].Replies[0].By)
+	assert.Equal(t, "bob", comments[1].By)
+	assert.Empty(t, comments[1].Replies)
+}
+
+func TestFetchCommentsRespectsMaxDepth(t *testing.T) {
+	items := map[int]HNItem{
+		1: {ID: 1, Kids: []int{2}},
+		2: {ID: 2, By: "alice", Kids: []int{3}},
+		3: {ID: 3, By: "bob"},
+	}
+
+	server := fakeHNItemsServer(items)
+	defer server.Close()
+
+	origBaseURL, origClient := hnBaseURL, httpClient
+	hnBaseURL = server.URL
+	httpClient = server.Client()
+	defer func() { hnBaseURL, httpClient = origBaseURL, origClient }()
+
+	comments, err := fetchComments(context.Background(), 1, 1, defaultCommentMaxNodes)
+	assert.NoError(t, err)
+	assert.Len(t, comments, 1)
+	assert.Equal(t, "alice", comments[0].By)
+	assert.Empty(t, comments[0].Replies, "depth cap should have stopped before fetching bob's reply")
+}
+
+func TestFlattenComments(t *testing.T) {
+	tree := []Comment{
+		{ID: 1, By: "alice", Replies: []Comment{
+			{ID: 2, By: "bob"},
+		}},
+		{ID: 3, By: "carol"},
+	}
+
+	flat := flattenComments(tree, 0)
+
+	assert.Equal(t, []int{1, 2, 3}, []int{flat[0].ID, flat[1].ID, flat[2].ID})
+	assert.Equal(t, []int{0, 1, 0}, []int{flat[0].Depth, flat[1].Depth, flat[2].Depth})
+	for _, fc := range flat {
+		assert.Nil(t, fc.Replies, "flattened comments should not carry nested replies")
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFetchStoriesContextCancellation asserts that canceling the sole
+// caller's context both unblocks fetchStories immediately and actually
+// aborts the in-flight upstream request, rather than leaving it to run to
+// completion in the background.
+func TestFetchStoriesContextCancellation(t *testing.T) {
+	aborted := make(chan bool, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v0/topstories.json", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(200 * time.Millisecond):
+			aborted <- false
+			json.NewEncoder(w).Encode([]int{1})
+		case <-r.Context().Done():
+			aborted <- true
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	origBaseURL, origClient, origCache := hnBaseURL, httpClient, cache
+	hnBaseURL = server.URL
+	httpClient = server.Client()
+	cache = newMemoryCache[[]Story]()
+	defer func() {
+		hnBaseURL, httpClient, cache = origBaseURL, origClient, origCache
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := fetchStories(ctx, "top")
+	elapsed := time.Since(start)
+
+	assert.Error(t, err)
+	assert.Less(t, elapsed, 200*time.Millisecond, "should return once the caller's context is done, not wait for the slow response")
+
+	select {
+	case wasAborted := <-aborted:
+		assert.True(t, wasAborted, "the upstream request should have been canceled once its sole caller gave up, not left to run to completion")
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("upstream handler never observed cancellation or completion")
+	}
+}
+
+// TestFetchStoriesUncachedBoundsConcurrency asserts that item fetches are
+// bounded to itemFetchWorkers concurrent requests, not one per story.
+func TestFetchStoriesUncachedBoundsConcurrency(t *testing.T) {
+	const storyCount = 20
+
+	var inFlight, maxInFlight int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v0/topstories.json", func(w http.ResponseWriter, r *http.Request) {
+		ids := make([]int, storyCount)
+		for i := range ids {
+			ids[i] = i + 1
+		}
+		json.NewEncoder(w).Encode(ids)
+	})
+	mux.HandleFunc("/v0/item/", func(w http.ResponseWriter, r *http.Request) {
+		cur := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if cur <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, cur) {
+				break
+			}
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		var id int
+		fmt.Sscanf(r.URL.Path, "/v0/item/%d.json", &id)
+		json.NewEncoder(w).Encode(HNItem{ID: id, Title: "story", By: "tester", Time: time.Now().Unix()})
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	origBaseURL, origClient, origCache := hnBaseURL, httpClient, cache
+	hnBaseURL = server.URL
+	httpClient = server.Client()
+	cache = newMemoryCache[[]Story]()
+	defer func() {
+		hnBaseURL, httpClient, cache = origBaseURL, origClient, origCache
+	}()
+
+	stories, err := fetchStories(context.Background(), "top")
+	assert.NoError(t, err)
+	assert.Len(t, stories, storyCount)
+	assert.LessOrEqual(t, int(atomic.LoadInt32(&maxInFlight)), itemFetchWorkers)
+}
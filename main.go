@@ -1,20 +1,66 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
 	"strings"
 	"sync"
 	"time"
 
 	_ "github.com/cloudbees-days/hackers-api/docs" // Import generated Swagger docs
 
+	"github.com/cloudbees-days/hackers-api/render"
+	"github.com/cloudbees-days/hackers-api/web"
+	"github.com/gin-contrib/static"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
 )
 
+const (
+	defaultFetchTimeout = 5 * time.Second
+	itemFetchWorkers    = 8
+)
+
+// httpClient is used for all outbound Firebase HN API calls. Its Transport
+// is tuned to reuse connections across the many item fetches a single
+// request fans out to. It is a package var so tests can point it at an
+// httptest.Server.
+var httpClient = &http.Client{
+	Transport: &http.Transport{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: itemFetchWorkers * 2,
+		IdleConnTimeout:     90 * time.Second,
+	},
+}
+
+// hnBaseURL is the root of the Firebase HN API. It is a package var so
+// tests can point it at an httptest.Server.
+var hnBaseURL = "https://hacker-news.firebaseio.com"
+
+// fetchGroup collapses concurrent cache-miss fetches for the same story
+// type into a single upstream call.
+var fetchGroup singleflight.Group
+
+// fetchTimeout is the overall deadline for an upstream fetch, covering the
+// story-ID listing and every fanned-out item fetch. It is independent of
+// any single caller's request context, since a fetch triggered by one
+// request may be shared with other in-flight requests via fetchGroup.
+// Configurable via FETCH_TIMEOUT (a Go duration string, e.g. "10s").
+func fetchTimeout() time.Duration {
+	if raw := os.Getenv("FETCH_TIMEOUT"); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil {
+			return d
+		}
+	}
+	return defaultFetchTimeout
+}
+
 // @title           Hackers API
 // @version         1.0
 // @description     A simple API that mirrors Hacker News content
@@ -46,78 +92,130 @@ type HNItem struct {
 	Time        int64  `json:"time"`
 	Title       string `json:"title"`
 	URL         string `json:"url"`
+	Text        string `json:"text"`
 	Score       int    `json:"score"`
 	Descendants int    `json:"descendants"`
+	Parent      int    `json:"parent"`
+	Kids        []int  `json:"kids"`
+	Deleted     bool   `json:"deleted"`
+	Dead        bool   `json:"dead"`
 }
 
-// Cache structure
-type StoriesCache struct {
-	stories    map[string][]Story
-	lastUpdate map[string]time.Time
-	mutex      sync.RWMutex
+// fetchWaiters tracks, per story type, how many callers are still waiting
+// on the singleflight-shared upstream fetch and the cancel func for that
+// fetch's context. A shared fetch outlives any single caller's context by
+// design (fetchGroup may be serving several callers), but when the last
+// remaining waiter's context is done, there is no one left to hand the
+// result to, so the fetch itself is canceled instead of running to
+// completion for no one.
+type fetchWaiters struct {
+	mu      sync.Mutex
+	cancel  map[string]context.CancelFunc
+	waiters map[string]int
 }
 
-const (
-	cacheExpiration = 5 * time.Minute
-	maxStories      = 30
-)
-
-var cache = &StoriesCache{
-	stories:    make(map[string][]Story),
-	lastUpdate: make(map[string]time.Time),
+var pendingFetches = &fetchWaiters{
+	cancel:  make(map[string]context.CancelFunc),
+	waiters: make(map[string]int),
 }
 
-func (sc *StoriesCache) get(storyType string) ([]Story, bool) {
-	sc.mutex.RLock()
-	defer sc.mutex.RUnlock()
+func (f *fetchWaiters) join(key string) {
+	f.mu.Lock()
+	f.waiters[key]++
+	f.mu.Unlock()
+}
 
-	lastUpdate, ok := sc.lastUpdate[storyType]
-	if !ok {
-		return nil, false
+// leave records that a waiter is no longer interested in key's fetch. If
+// it was the last one and the fetch is still in flight, the fetch is
+// canceled.
+func (f *fetchWaiters) leave(key string) {
+	f.mu.Lock()
+	f.waiters[key]--
+	remaining := f.waiters[key]
+	cancel := f.cancel[key]
+	if remaining <= 0 {
+		delete(f.waiters, key)
 	}
+	f.mu.Unlock()
 
-	if time.Since(lastUpdate) > cacheExpiration {
-		return nil, false
+	if remaining <= 0 && cancel != nil {
+		cancel()
 	}
-
-	stories, ok := sc.stories[storyType]
-	return stories, ok
 }
 
-func (sc *StoriesCache) set(storyType string, stories []Story) {
-	sc.mutex.Lock()
-	defer sc.mutex.Unlock()
+func (f *fetchWaiters) setCancel(key string, cancel context.CancelFunc) {
+	f.mu.Lock()
+	f.cancel[key] = cancel
+	f.mu.Unlock()
+}
 
-	sc.stories[storyType] = stories
-	sc.lastUpdate[storyType] = time.Now()
+func (f *fetchWaiters) clearCancel(key string) {
+	f.mu.Lock()
+	delete(f.cancel, key)
+	f.mu.Unlock()
 }
 
-func fetchStories(storyType string) ([]Story, error) {
+// fetchStories returns stories of the given type, serving from cache when
+// possible. The underlying upstream fetch runs on its own bounded
+// fetchTimeout rather than ctx directly, since it may be shared with other
+// in-flight requests via fetchGroup — but if ctx is the last one watching
+// it, it cancels the shared fetch rather than leaving it to run to
+// completion for an audience of none.
+func fetchStories(ctx context.Context, storyType string) ([]Story, error) {
 	// Check cache first
-	if stories, ok := cache.get(storyType); ok {
+	if stories, ok := cache.Get(ctx, storyType); ok {
+		return stories, nil
+	}
+
+	pendingFetches.join(storyType)
+
+	resultCh := fetchGroup.DoChan(storyType, func() (interface{}, error) {
+		fetchCtx, cancel := context.WithTimeout(context.Background(), fetchTimeout())
+		pendingFetches.setCancel(storyType, cancel)
+		defer func() {
+			pendingFetches.clearCancel(storyType)
+			cancel()
+		}()
+		return fetchStoriesUncached(fetchCtx, storyType)
+	})
+
+	select {
+	case <-ctx.Done():
+		pendingFetches.leave(storyType)
+		return nil, ctx.Err()
+	case r := <-resultCh:
+		pendingFetches.leave(storyType)
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		stories := r.Val.([]Story)
+		cache.Set(ctx, storyType, stories, cacheTTL())
 		return stories, nil
 	}
+}
 
-	var endpoint string
+// storyEndpoint maps a story type to its Firebase HN API endpoint.
+func storyEndpoint(storyType string) (string, error) {
 	switch storyType {
 	case "top":
-		endpoint = "topstories"
+		return "topstories", nil
 	case "show":
-		endpoint = "showstories"
+		return "showstories", nil
 	case "ask":
-		endpoint = "askstories"
+		return "askstories", nil
 	default:
-		return nil, fmt.Errorf("invalid story type: %s", storyType)
+		return "", fmt.Errorf("invalid story type: %s", storyType)
 	}
+}
 
-	resp, err := http.Get(fmt.Sprintf("https://hacker-news.firebaseio.com/v0/%s.json", endpoint))
+func fetchStoriesUncached(ctx context.Context, storyType string) ([]Story, error) {
+	endpoint, err := storyEndpoint(storyType)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	var storyIDs []int
-	if err := json.NewDecoder(resp.Body).Decode(&storyIDs); err != nil {
+	storyIDs, err := fetchStoryIDs(ctx, endpoint)
+	if err != nil {
 		return nil, err
 	}
 
@@ -125,10 +223,32 @@ func fetchStories(storyType string) ([]Story, error) {
 		storyIDs = storyIDs[:maxStories]
 	}
 
+	// Fan out item fetches across a bounded worker pool so one request
+	// completes in ~1 round-trip instead of len(storyIDs) sequential ones.
+	// A shared cancellation (fetchCtx here, or client disconnect via
+	// getStories) unblocks every in-flight fetch at once.
+	items := make([]*HNItem, len(storyIDs))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(itemFetchWorkers)
+	for i, id := range storyIDs {
+		i, id := i, id
+		g.Go(func() error {
+			item, err := fetchItem(gctx, id)
+			if err != nil {
+				// A single failed item shouldn't fail the whole request.
+				return nil
+			}
+			items[i] = item
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
 	stories := make([]Story, 0, len(storyIDs))
-	for _, id := range storyIDs {
-		item, err := fetchItem(id)
-		if err != nil {
+	for _, item := range items {
+		if item == nil {
 			continue
 		}
 
@@ -153,13 +273,36 @@ func fetchStories(storyType string) ([]Story, error) {
 		stories = append(stories, story)
 	}
 
-	// Update cache
-	cache.set(storyType, stories)
 	return stories, nil
 }
 
-func fetchItem(id int) (*HNItem, error) {
-	resp, err := http.Get(fmt.Sprintf("https://hacker-news.firebaseio.com/v0/item/%d.json", id))
+func fetchStoryIDs(ctx context.Context, endpoint string) ([]int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v0/%s.json", hnBaseURL, endpoint), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var storyIDs []int
+	if err := json.NewDecoder(resp.Body).Decode(&storyIDs); err != nil {
+		return nil, err
+	}
+
+	return storyIDs, nil
+}
+
+func fetchItem(ctx context.Context, id int) (*HNItem, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v0/item/%d.json", hnBaseURL, id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -174,11 +317,12 @@ func fetchItem(id int) (*HNItem, error) {
 }
 
 // @Summary     Get stories
-// @Description Get stories from Hacker News based on type
+// @Description Get stories from Hacker News based on type, as JSON, RSS, or Atom
 // @Tags        stories
 // @Accept      json
-// @Produce     json
-// @Param       type path string false "Story type (top/show/ask)"
+// @Produce     json,xml
+// @Param       type   path  string false "Story type (top/show/ask)"
+// @Param       format query string false "Response format: json (default), rss, or atom"
 // @Success     200 {array} Story
 // @Failure     500 {object} ErrorResponse
 // @Router      /stories/{type} [get]
@@ -188,13 +332,88 @@ func getStories(c *gin.Context) {
 		storyType = "top"
 	}
 
-	stories, err := fetchStories(storyType)
+	stories, err := fetchStories(c.Request.Context(), storyType)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, stories)
+	render.WriteStories(c, storyType, toRenderStories(stories), render.NegotiateFormat(c))
+}
+
+// @Summary     Stream new stories
+// @Description Server-Sent Events stream of newly observed stories for a given type
+// @Tags        stories
+// @Produce     text/event-stream
+// @Param       type path string false "Story type (top/show/ask)"
+// @Success     200 {object} Story
+// @Failure     500 {object} ErrorResponse
+// @Router      /stories/{type}/stream [get]
+func streamStories(c *gin.Context) {
+	storyType := c.Param("type")
+	if storyType == "" {
+		storyType = "top"
+	}
+
+	if _, err := storyEndpoint(storyType); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ch := storyBroker.Subscribe(storyType)
+	defer storyBroker.Unsubscribe(storyType, ch)
+
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+
+	flusher, canFlush := c.Writer.(http.Flusher)
+
+	keepalive := time.NewTicker(keepaliveInterval)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case story, ok := <-ch:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(story)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(c.Writer, "event: story\ndata: %s\n\n", data)
+			if canFlush {
+				flusher.Flush()
+			}
+		case <-keepalive.C:
+			fmt.Fprint(c.Writer, ":keepalive\n\n")
+			if canFlush {
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// toRenderStories converts stories to the render package's Story type so
+// the render package doesn't need to import package main.
+func toRenderStories(stories []Story) []render.Story {
+	out := make([]render.Story, len(stories))
+	for i, s := range stories {
+		out[i] = render.Story{
+			ID:          s.ID,
+			Title:       s.Title,
+			URL:         s.URL,
+			Points:      s.Points,
+			SubmittedBy: s.SubmittedBy,
+			CreatedAt:   s.CreatedAt,
+			CommentsURL: s.CommentsURL,
+			Type:        s.Type,
+		}
+	}
+	return out
 }
 
 func main() {
@@ -217,12 +436,33 @@ func main() {
 	// API routes
 	api := r.Group("/api")
 	{
-		api.GET("/stories", getStories)       // Default to top stories
-		api.GET("/stories/:type", getStories) // Get stories by type (top/show/ask)
+		api.GET("/stories", getStories)                 // Default to top stories
+		api.GET("/stories/:type", getStories)           // Get stories by type (top/show/ask)
+		api.GET("/stories/:type/stream", streamStories) // SSE stream of newly observed stories
+		api.GET("/stories/:type/comments", getComments) // Comment tree; :type holds the story ID here
 	}
 
 	// Swagger documentation endpoint
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Bundled SPA frontend. Registered after the API and Swagger routes so
+	// their handler chains are unaffected; static.Serve only intercepts
+	// requests that don't match a route registered before it.
+	webDir := os.Getenv("WEB_DIR")
+	r.Use(static.Serve("/", web.FileSystem(webDir)))
+	r.NoRoute(func(c *gin.Context) {
+		if strings.HasPrefix(c.Request.URL.Path, "/api") || strings.HasPrefix(c.Request.URL.Path, "/swagger") {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "not found"})
+			return
+		}
+
+		index, err := web.IndexHTML(webDir)
+		if err != nil {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "not found"})
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", index)
+	})
+
 	r.Run(":8080")
 }
@@ -61,7 +61,7 @@ func TestGetStoriesEndpoint(t *testing.T) {
 					assert.NotEmpty(t, story.SubmittedBy, "Story submitter should not be empty")
 					assert.NotZero(t, story.CreatedAt, "Story creation time should not be zero")
 					assert.Contains(t, story.CommentsURL, "news.ycombinator.com/item", "Comments URL should be a valid HN URL")
-					assert.GreaterOrEqual(t, story.Comments, 0, "Comments count should be non-negative")
+					assert.GreaterOrEqual(t, story.Points, 0, "Points should be non-negative")
 				}
 			},
 		},
@@ -120,10 +120,7 @@ func TestGetStoriesEndpoint(t *testing.T) {
 // TestCaching tests the caching functionality
 func TestCaching(t *testing.T) {
 	// Reset cache for testing
-	cache = &StoriesCache{
-		stories:    make(map[string][]Story),
-		lastUpdate: make(map[string]time.Time),
-	}
+	cache = newMemoryCache[[]Story]()
 
 	router := setupRouter()
 
@@ -0,0 +1,136 @@
+package render
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+const (
+	feedTitlePrefix = "Hacker News"
+	feedDescription = "Stories mirrored from Hacker News"
+	feedSelfLink    = "https://news.ycombinator.com/"
+)
+
+// rssFeed is the RSS 2.0 document root: <rss><channel>...</channel></rss>.
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Items       []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title    string `xml:"title"`
+	Link     string `xml:"link"`
+	Author   string `xml:"author,omitempty"`
+	PubDate  string `xml:"pubDate"`
+	GUID     string `xml:"guid"`
+	Comments string `xml:"comments,omitempty"`
+}
+
+// RSSFeed renders stories of the given type as an RSS 2.0 document,
+// including the XML declaration.
+func RSSFeed(storyType string, stories []Story) []byte {
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:       fmt.Sprintf("%s: %s", feedTitlePrefix, storyType),
+			Link:        feedSelfLink,
+			Description: feedDescription,
+			Items:       make([]rssItem, 0, len(stories)),
+		},
+	}
+
+	for _, s := range stories {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:    s.Title,
+			Link:     storyLink(s),
+			Author:   s.SubmittedBy,
+			PubDate:  s.CreatedAt.Format(time.RFC1123Z),
+			GUID:     s.CommentsURL,
+			Comments: s.CommentsURL,
+		})
+	}
+
+	return marshalXML(feed)
+}
+
+// atomFeed is the Atom 1.0 document root: <feed>...<entry>...</entry></feed>.
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Link    atomLink    `xml:"link"`
+	Updated string      `xml:"updated"`
+	Author  *atomAuthor `xml:"author,omitempty"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+// AtomFeed renders stories of the given type as an Atom 1.0 document,
+// including the XML declaration.
+func AtomFeed(storyType string, stories []Story) []byte {
+	feed := atomFeed{
+		Xmlns:   "http://www.w3.org/2005/Atom",
+		Title:   fmt.Sprintf("%s: %s", feedTitlePrefix, storyType),
+		ID:      feedSelfLink,
+		Updated: time.Now().UTC().Format(time.RFC3339),
+		Entries: make([]atomEntry, 0, len(stories)),
+	}
+
+	for _, s := range stories {
+		var author *atomAuthor
+		if s.SubmittedBy != "" {
+			author = &atomAuthor{Name: s.SubmittedBy}
+		}
+
+		feed.Entries = append(feed.Entries, atomEntry{
+			Title:   s.Title,
+			ID:      s.CommentsURL,
+			Link:    atomLink{Href: storyLink(s)},
+			Updated: s.CreatedAt.UTC().Format(time.RFC3339),
+			Author:  author,
+		})
+	}
+
+	return marshalXML(feed)
+}
+
+// storyLink prefers the story's external URL (e.g. "Show HN" links to the
+// project itself) and falls back to the HN comments page for link-less
+// "Ask HN" posts.
+func storyLink(s Story) string {
+	if s.URL != "" {
+		return s.URL
+	}
+	return s.CommentsURL
+}
+
+func marshalXML(v interface{}) []byte {
+	out, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil
+	}
+	return append([]byte(xml.Header), out...)
+}
@@ -0,0 +1,79 @@
+// Package render converts Hacker News stories into the response formats the
+// /api/stories endpoints can serve: plain JSON (the default), RSS 2.0, and
+// Atom 1.0.
+package render
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Format identifies one of the response formats the render package knows
+// how to produce.
+type Format string
+
+const (
+	JSON Format = "json"
+	RSS  Format = "rss"
+	Atom Format = "atom"
+)
+
+// Story is the subset of story data needed to build a feed. It mirrors the
+// main package's Story type field-for-field; it is duplicated here rather
+// than imported to avoid main <-> render becoming an import cycle.
+type Story struct {
+	ID          int
+	Title       string
+	URL         string
+	Points      int
+	SubmittedBy string
+	CreatedAt   time.Time
+	CommentsURL string
+	Type        string
+}
+
+// NegotiateFormat determines which format to render in. The `format` query
+// parameter takes precedence over the Accept header, mirroring the
+// override pattern Echo's binder uses for content negotiation. An unknown
+// or absent format value defers to the Accept header; if that names no
+// feed type either, the result is JSON.
+func NegotiateFormat(c *gin.Context) Format {
+	switch strings.ToLower(c.Query("format")) {
+	case "rss":
+		return RSS
+	case "atom":
+		return Atom
+	case "json":
+		return JSON
+	}
+
+	accept := c.GetHeader("Accept")
+	for _, part := range strings.Split(accept, ",") {
+		switch strings.TrimSpace(strings.SplitN(part, ";", 2)[0]) {
+		case "application/rss+xml":
+			return RSS
+		case "application/atom+xml":
+			return Atom
+		case "application/xml", "text/xml":
+			return RSS
+		}
+	}
+
+	return JSON
+}
+
+// WriteStories renders stories in the negotiated format and writes them to
+// the response, setting the appropriate Content-Type.
+func WriteStories(c *gin.Context, storyType string, stories []Story, format Format) {
+	switch format {
+	case RSS:
+		c.Data(http.StatusOK, "application/rss+xml; charset=utf-8", RSSFeed(storyType, stories))
+	case Atom:
+		c.Data(http.StatusOK, "application/atom+xml; charset=utf-8", AtomFeed(storyType, stories))
+	default:
+		c.JSON(http.StatusOK, stories)
+	}
+}
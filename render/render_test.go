@@ -0,0 +1,124 @@
+package render
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func sampleStories(storyType string) []Story {
+	return []Story{
+		{
+			ID:          1,
+			Title:       "Show HN: Cool Project",
+			URL:         "https://example.com/cool",
+			Points:      42,
+			SubmittedBy: "johndoe",
+			CreatedAt:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			CommentsURL: "https://news.ycombinator.com/item?id=1",
+			Type:        storyType,
+		},
+		{
+			ID:          2,
+			Title:       "Ask HN: How do you deploy?",
+			SubmittedBy: "janedoe",
+			CreatedAt:   time.Date(2024, 1, 3, 3, 4, 5, 0, time.UTC),
+			CommentsURL: "https://news.ycombinator.com/item?id=2",
+			Type:        storyType,
+		},
+	}
+}
+
+func TestNegotiateFormat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		query  string
+		accept string
+		want   Format
+	}{
+		{"query overrides accept", "format=rss", "application/json", RSS},
+		{"atom query", "format=atom", "", Atom},
+		{"rss accept header", "", "application/rss+xml", RSS},
+		{"atom accept header", "", "application/atom+xml", Atom},
+		{"generic xml accept treated as rss", "", "application/xml", RSS},
+		{"text/xml accept treated as rss", "", "text/xml", RSS},
+		{"default json", "", "", JSON},
+		{"unknown query falls back to json", "format=yaml", "", JSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			req, _ := http.NewRequest("GET", "/api/stories?"+tt.query, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			c.Request = req
+
+			assert.Equal(t, tt.want, NegotiateFormat(c))
+		})
+	}
+}
+
+func TestWriteStoriesJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/api/stories/top", nil)
+
+	WriteStories(c, "top", sampleStories("top"), JSON)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+	assert.Contains(t, w.Body.String(), "Show HN: Cool Project")
+}
+
+func TestWriteStoriesRSS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, storyType := range []string{"top", "show", "ask"} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("GET", "/api/stories/"+storyType, nil)
+
+		WriteStories(c, storyType, sampleStories(storyType), RSS)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "application/rss+xml")
+
+		var feed rssFeed
+		err := xml.Unmarshal(w.Body.Bytes(), &feed)
+		assert.NoError(t, err)
+		assert.Len(t, feed.Channel.Items, 2)
+		assert.Equal(t, "https://example.com/cool", feed.Channel.Items[0].Link)
+	}
+}
+
+func TestWriteStoriesAtom(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, storyType := range []string{"top", "show", "ask"} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("GET", "/api/stories/"+storyType, nil)
+
+		WriteStories(c, storyType, sampleStories(storyType), Atom)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "application/atom+xml")
+
+		var feed atomFeed
+		err := xml.Unmarshal(w.Body.Bytes(), &feed)
+		assert.NoError(t, err)
+		assert.Len(t, feed.Entries, 2)
+		assert.Equal(t, "janedoe", feed.Entries[1].Author.Name)
+	}
+}
@@ -0,0 +1,68 @@
+// Package web serves the SPA frontend bundled alongside the API. The
+// built assets are embedded into the binary via embed.FS so a single `go
+// build` produces a self-contained artifact; in development, FileSystem
+// prefers an on-disk directory (WEB_DIR) so a running server picks up a
+// fresh `npm run build` without recompiling this binary.
+package web
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gin-contrib/static"
+)
+
+//go:embed dist
+var embedded embed.FS
+
+// FileSystem returns the static.ServeFileSystem to mount the SPA from:
+// webDir on disk if it exists, otherwise the assets embedded at build
+// time.
+func FileSystem(webDir string) static.ServeFileSystem {
+	if isDir(webDir) {
+		return static.LocalFile(webDir, true)
+	}
+
+	sub, err := fs.Sub(embedded, "dist")
+	if err != nil {
+		// dist is embedded at build time via the go:embed directive above;
+		// this can only fail if that directive itself is broken.
+		panic(err)
+	}
+	return embedFS{http.FS(sub)}
+}
+
+// IndexHTML returns index.html's contents from the active filesystem, for
+// SPA client-side routes that don't match a static asset.
+func IndexHTML(webDir string) ([]byte, error) {
+	if isDir(webDir) {
+		return os.ReadFile(filepath.Join(webDir, "index.html"))
+	}
+	return embedded.ReadFile("dist/index.html")
+}
+
+func isDir(path string) bool {
+	if path == "" {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// embedFS adapts an http.FileSystem backed by embed.FS to
+// static.ServeFileSystem, which additionally requires Exists.
+type embedFS struct {
+	http.FileSystem
+}
+
+func (e embedFS) Exists(_ string, path string) bool {
+	f, err := e.Open(path)
+	if err != nil {
+		return false
+	}
+	f.Close()
+	return true
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cloudbees-days/hackers-api/web"
+	"github.com/gin-contrib/static"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupRouterWithWeb mirrors main()'s bundled SPA frontend setup (static
+// files plus the NoRoute fallback) on a router with only the /api/stories
+// route, for tests to exercise directly.
+func setupRouterWithWeb(webDir string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+
+	api := r.Group("/api")
+	{
+		api.GET("/stories", getStories)
+	}
+
+	r.Use(static.Serve("/", web.FileSystem(webDir)))
+	r.NoRoute(func(c *gin.Context) {
+		if strings.HasPrefix(c.Request.URL.Path, "/api") || strings.HasPrefix(c.Request.URL.Path, "/swagger") {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "not found"})
+			return
+		}
+
+		index, err := web.IndexHTML(webDir)
+		if err != nil {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "not found"})
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", index)
+	})
+
+	return r
+}
+
+func TestServeSPAIndex(t *testing.T) {
+	router := setupRouterWithWeb("")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Body.String(), `<div id="app">`)
+}
+
+func TestServeSPAClientRouteFallsBackToIndex(t *testing.T) {
+	router := setupRouterWithWeb("")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/some/spa/route", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `<div id="app">`, "unknown client routes should fall back to index.html")
+}
+
+func TestServeSPAStaticAsset(t *testing.T) {
+	router := setupRouterWithWeb("")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/assets/app.js", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "javascript")
+}
+
+func TestServeSPAAPIRoutesTakePrecedence(t *testing.T) {
+	router := setupRouterWithWeb("")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/missing", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json", "unmatched /api routes must not fall back to the SPA index")
+}